@@ -0,0 +1,102 @@
+package evidence
+
+import (
+	"fmt"
+	"sync"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// EvidencePool is the lifecycle home for the Evidence interface: it stores
+// pending evidence received via gossip, deduplicates it against what it
+// already knows (pending or committed), hands it to the consensus reactor
+// for inclusion in proposals, and prunes it once it is too old to matter.
+type EvidencePool struct {
+	logger log.Logger
+
+	store *EvidenceStore
+
+	mtx    sync.Mutex
+	params types.EvidenceParams
+
+	evidenceCh chan types.Evidence
+}
+
+// NewEvidencePool returns an EvidencePool backed by db, pruning pending
+// evidence according to params.MaxAge.
+func NewEvidencePool(db dbm.DB, params types.EvidenceParams) *EvidencePool {
+	return &EvidencePool{
+		logger:     log.NewNopLogger(),
+		store:      NewEvidenceStore(db),
+		params:     params,
+		evidenceCh: make(chan types.Evidence, 1000),
+	}
+}
+
+// SetLogger sets the EvidencePool's logger.
+func (evpool *EvidencePool) SetLogger(l log.Logger) {
+	evpool.logger = l
+}
+
+// AddEvidence stores evidence as pending and publishes it on EvidenceChan,
+// unless it is already known. AddEvidence does not itself verify evidence:
+// that requires context (a ValidatorSet, a reference header) this pool
+// does not hold, so verification is the gossip reactor's responsibility
+// before it calls AddEvidence.
+func (evpool *EvidencePool) AddEvidence(evidence types.Evidence) error {
+	if evpool.store.Has(evidence) {
+		return nil
+	}
+
+	if err := evpool.store.AddPendingEvidence(evidence); err != nil {
+		return fmt.Errorf("EvidencePool: failed to add evidence: %v", err)
+	}
+
+	select {
+	case evpool.evidenceCh <- evidence:
+	default:
+		evpool.logger.Error("EvidencePool: evidence channel full, dropping notification", "evidence", evidence)
+	}
+
+	return nil
+}
+
+// PendingEvidence returns up to maxBytes worth of pending evidence, for
+// inclusion in the next proposed block. maxBytes should be
+// types.MaxEvidenceBytesPerBlock(blockMaxBytes, numValidators).
+func (evpool *EvidencePool) PendingEvidence(maxBytes int) []types.Evidence {
+	return evpool.store.PendingEvidence(maxBytes)
+}
+
+// MarkEvidenceAsCommitted moves evidence committed at height out of the
+// pending set, so it is never proposed again.
+func (evpool *EvidencePool) MarkEvidenceAsCommitted(height int64, evidence []types.Evidence) {
+	evpool.store.MarkEvidenceAsCommitted(height, evidence)
+}
+
+// Update replaces the EvidenceParams used by Cleanup, e.g. after a
+// consensus-params change is applied at a new height.
+func (evpool *EvidencePool) Update(params types.EvidenceParams) {
+	evpool.mtx.Lock()
+	defer evpool.mtx.Unlock()
+	evpool.params = params
+}
+
+// Cleanup removes pending evidence older than params.MaxAge blocks relative
+// to currentHeight, in a single range scan over the store.
+func (evpool *EvidencePool) Cleanup(currentHeight int64) {
+	evpool.mtx.Lock()
+	maxAge := evpool.params.MaxAge
+	evpool.mtx.Unlock()
+
+	evpool.store.removeExpiredPending(currentHeight, maxAge)
+}
+
+// EvidenceChan returns a channel on which newly-added evidence is
+// published, so the consensus reactor can pick it up for gossip and
+// inclusion in proposals.
+func (evpool *EvidencePool) EvidenceChan() <-chan types.Evidence {
+	return evpool.evidenceCh
+}
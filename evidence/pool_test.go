@@ -0,0 +1,57 @@
+package evidence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEvidencePoolAddAndPending(t *testing.T) {
+	pool := NewEvidencePool(dbm.NewMemDB(), types.EvidenceParams{MaxAge: 10})
+
+	ev := types.NewMockGoodEvidence(1, 0, []byte("validator"))
+
+	require.NoError(t, pool.AddEvidence(ev))
+	require.Len(t, pool.PendingEvidence(0), 1)
+
+	// Adding the same evidence again is a no-op.
+	require.NoError(t, pool.AddEvidence(ev))
+	require.Len(t, pool.PendingEvidence(0), 1)
+}
+
+func TestEvidencePoolMarkEvidenceAsCommitted(t *testing.T) {
+	pool := NewEvidencePool(dbm.NewMemDB(), types.EvidenceParams{MaxAge: 10})
+
+	// Evidence proves an equivocation at height 5, but - as is normal - it
+	// is only actually committed in a later block, at height 8. The
+	// pending entry must still be found and removed by evidence height,
+	// not by the committing block's height.
+	ev := types.NewMockGoodEvidence(5, 0, []byte("validator"))
+	require.NoError(t, pool.AddEvidence(ev))
+
+	pool.MarkEvidenceAsCommitted(8, []types.Evidence{ev})
+
+	require.Empty(t, pool.PendingEvidence(0))
+	// Has must still recognize the evidence as known, now as committed, or
+	// AddEvidence will re-accept it as pending and it will be proposed
+	// again forever.
+	require.True(t, pool.store.Has(ev))
+}
+
+func TestEvidencePoolCleanup(t *testing.T) {
+	pool := NewEvidencePool(dbm.NewMemDB(), types.EvidenceParams{MaxAge: 10})
+
+	oldEv := types.NewMockGoodEvidence(1, 0, []byte("validator"))
+	freshEv := types.NewMockGoodEvidence(95, 0, []byte("validator"))
+	require.NoError(t, pool.AddEvidence(oldEv))
+	require.NoError(t, pool.AddEvidence(freshEv))
+
+	pool.Cleanup(100)
+
+	pending := pool.PendingEvidence(0)
+	require.Len(t, pending, 1)
+	require.Equal(t, int64(95), pending[0].Height())
+}
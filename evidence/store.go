@@ -0,0 +1,143 @@
+package evidence
+
+import (
+	"fmt"
+
+	amino "github.com/tendermint/go-amino"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+var cdc = amino.NewCodec()
+
+func init() {
+	types.RegisterEvidences(cdc)
+}
+
+// EvidenceInfo is the unit stored in the evidence db: the evidence itself,
+// plus whether it has already been committed to a block.
+type EvidenceInfo struct {
+	Committed bool
+	Evidence  types.Evidence
+}
+
+// keyPending and keyCommitted follow the same height-indexed layout used by
+// the lite client's trusted-header store: a sortable, zero-padded height
+// prefix lets Cleanup and PendingEvidence range-scan by height instead of
+// keeping a separate in-memory index.
+func keyPending(height int64, hash []byte) []byte {
+	return []byte(fmt.Sprintf("pending/%020d/%X", height, hash))
+}
+
+func keyCommitted(height int64, hash []byte) []byte {
+	return []byte(fmt.Sprintf("committed/%020d/%X", height, hash))
+}
+
+// EvidenceStore persists Evidence to a key-value db, keyed by height and
+// hash so pending and committed evidence can each be range-scanned
+// independently.
+type EvidenceStore struct {
+	db dbm.DB
+}
+
+// NewEvidenceStore returns an EvidenceStore backed by db.
+func NewEvidenceStore(db dbm.DB) *EvidenceStore {
+	return &EvidenceStore{db: db}
+}
+
+// Has returns true if evidence is already known, pending or committed.
+func (store *EvidenceStore) Has(evidence types.Evidence) bool {
+	return store.db.Has(keyPending(evidence.Height(), evidence.Hash())) ||
+		store.db.Has(keyCommitted(evidence.Height(), evidence.Hash()))
+}
+
+// AddPendingEvidence stores evidence as pending.
+func (store *EvidenceStore) AddPendingEvidence(evidence types.Evidence) error {
+	ei := EvidenceInfo{Evidence: evidence}
+	bz, err := cdc.MarshalBinaryBare(ei)
+	if err != nil {
+		return err
+	}
+	store.db.Set(keyPending(evidence.Height(), evidence.Hash()), bz)
+	return nil
+}
+
+// PendingEvidence returns pending evidence, oldest first, stopping once the
+// accumulated amino-encoded size would exceed maxBytes. maxBytes <= 0 means
+// unbounded.
+func (store *EvidenceStore) PendingEvidence(maxBytes int) []types.Evidence {
+	var (
+		evidence []types.Evidence
+		bytes    int
+	)
+
+	iter := dbm.IteratePrefix(store.db, []byte("pending/"))
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var ei EvidenceInfo
+		if err := cdc.UnmarshalBinaryBare(iter.Value(), &ei); err != nil {
+			panic(fmt.Sprintf("EvidenceStore: failed to decode pending evidence: %v", err))
+		}
+
+		size := len(iter.Value())
+		if maxBytes > 0 && bytes+size > maxBytes {
+			break
+		}
+		bytes += size
+		evidence = append(evidence, ei.Evidence)
+	}
+
+	return evidence
+}
+
+// MarkEvidenceAsCommitted moves evidence committed in the block at height
+// from the pending set into the committed set, in a single batch write.
+// height, the height of the committing block, is not used or stored; both
+// the pending and committed entries are keyed by the evidence's own height
+// (ev.Height(), the height it proves an equivocation at) so that Has can
+// look either one up without knowing which block committed it.
+func (store *EvidenceStore) MarkEvidenceAsCommitted(height int64, evidence []types.Evidence) {
+	batch := store.db.NewBatch()
+	defer batch.Close()
+
+	for _, ev := range evidence {
+		batch.Delete(keyPending(ev.Height(), ev.Hash()))
+
+		bz, err := cdc.MarshalBinaryBare(EvidenceInfo{Committed: true, Evidence: ev})
+		if err != nil {
+			panic(fmt.Sprintf("EvidenceStore: failed to encode committed evidence: %v", err))
+		}
+		batch.Set(keyCommitted(ev.Height(), ev.Hash()), bz)
+	}
+
+	batch.Write()
+}
+
+// removeExpiredPending deletes all pending evidence at or below
+// currentHeight-maxAge, in a single range scan plus batch delete.
+func (store *EvidenceStore) removeExpiredPending(currentHeight, maxAge int64) {
+	expiredBelow := currentHeight - maxAge
+	if expiredBelow <= 0 {
+		return
+	}
+
+	iter := dbm.IteratePrefix(store.db, []byte("pending/"))
+	defer iter.Close()
+
+	batch := store.db.NewBatch()
+	defer batch.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var ei EvidenceInfo
+		if err := cdc.UnmarshalBinaryBare(iter.Value(), &ei); err != nil {
+			panic(fmt.Sprintf("EvidenceStore: failed to decode pending evidence: %v", err))
+		}
+		if ei.Evidence.Height() <= expiredBelow {
+			batch.Delete(iter.Key())
+		}
+	}
+
+	batch.Write()
+}
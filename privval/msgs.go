@@ -0,0 +1,74 @@
+package privval
+
+import (
+	amino "github.com/tendermint/go-amino"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/types"
+)
+
+// RemoteSignerMsg is sent between a SocketPV and its SignerRemote over the
+// wire. Requests flow from SocketPV to SignerRemote; the corresponding
+// *Reply/*Response flows back.
+type RemoteSignerMsg interface{}
+
+// RegisterRemoteSignerMsg registers all concrete RemoteSignerMsg types, as
+// well as the reply types from types.PrivValidator, with cdc.
+func RegisterRemoteSignerMsg(cdc *amino.Codec) {
+	cdc.RegisterInterface((*RemoteSignerMsg)(nil), nil)
+
+	cdc.RegisterConcrete(&PubKeyRequest{}, "tendermint/remotesigner/PubKeyRequest", nil)
+	cdc.RegisterConcrete(&PubKeyResponse{}, "tendermint/remotesigner/PubKeyResponse", nil)
+
+	cdc.RegisterConcrete(&SignVoteRequest{}, "tendermint/remotesigner/SignVoteRequest", nil)
+	cdc.RegisterConcrete(&types.SignVoteReply{}, "tendermint/remotesigner/SignVoteReply", nil)
+
+	cdc.RegisterConcrete(&SignProposalRequest{}, "tendermint/remotesigner/SignProposalRequest", nil)
+	cdc.RegisterConcrete(&types.SignProposalReply{}, "tendermint/remotesigner/SignProposalReply", nil)
+
+	cdc.RegisterConcrete(&SignHeartbeatRequest{}, "tendermint/remotesigner/SignHeartbeatRequest", nil)
+	cdc.RegisterConcrete(&types.SignHeartbeatReply{}, "tendermint/remotesigner/SignHeartbeatReply", nil)
+
+	cdc.RegisterConcrete(&types.PingRequest{}, "tendermint/remotesigner/PingRequest", nil)
+	cdc.RegisterConcrete(&types.PingResponse{}, "tendermint/remotesigner/PingResponse", nil)
+
+	cdc.RegisterConcrete(&types.RemoteSignerError{}, "tendermint/remotesigner/RemoteSignerError", nil)
+}
+
+// PubKeyRequest requests the pubkey of the remote signer's validator key.
+type PubKeyRequest struct{}
+
+// PubKeyResponse carries the pubkey, or an error if it could not be retrieved.
+type PubKeyResponse struct {
+	PubKey crypto.PubKey
+	Error  *types.RemoteSignerError
+}
+
+// SignVoteRequest requests a signature for Vote.
+type SignVoteRequest struct {
+	Vote *types.Vote
+}
+
+// SignProposalRequest requests a signature for Proposal.
+type SignProposalRequest struct {
+	ChainID  string
+	Proposal *types.Proposal
+}
+
+// SignHeartbeatRequest requests a signature for Heartbeat.
+type SignHeartbeatRequest struct {
+	ChainID   string
+	Heartbeat *types.Heartbeat
+}
+
+// remoteSignerErr is a convenience constructor for a *types.RemoteSignerError
+// wrapping a plain Go error, or nil if err is nil.
+func remoteSignerErr(err error) *types.RemoteSignerError {
+	if err == nil {
+		return nil
+	}
+	if rse, ok := err.(*types.RemoteSignerError); ok {
+		return rse
+	}
+	return &types.RemoteSignerError{Description: err.Error()}
+}
@@ -0,0 +1,144 @@
+package privval
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// SignerRemote runs alongside a key-holding types.PrivValidator (a MockPV in
+// tests, an HSM-backed implementation in production) and serves SocketPV's
+// requests over a connection it dials out itself. It redials whenever the
+// connection drops, waiting a fixed interval after each failed dial, so the
+// validator's listener
+// never needs to know the signer's address or retry logic.
+type SignerRemote struct {
+	cmn.BaseService
+
+	privVal types.PrivValidator
+	dialer  SignerDialer
+
+	quit chan struct{}
+}
+
+// NewSignerRemote returns a SignerRemote serving privVal's signatures to
+// whatever SocketPV dialer reaches.
+func NewSignerRemote(logger log.Logger, privVal types.PrivValidator, dialer SignerDialer) *SignerRemote {
+	sr := &SignerRemote{
+		privVal: privVal,
+		dialer:  dialer,
+		quit:    make(chan struct{}),
+	}
+	sr.BaseService = *cmn.NewBaseService(logger, "SignerRemote", sr)
+	return sr
+}
+
+// OnStart implements cmn.Service.
+func (sr *SignerRemote) OnStart() error {
+	go sr.loop()
+	return nil
+}
+
+// OnStop implements cmn.Service.
+func (sr *SignerRemote) OnStop() {
+	close(sr.quit)
+}
+
+// loop dials out, serves requests until the connection drops or errors,
+// then redials at once; only after a failed dial does it wait
+// defaultDialRetryWait before the next attempt. A successful connection resets the
+// failure count; after defaultConnRetries consecutive dial failures it
+// gives up and stops, rather than retrying forever against a SocketPV that
+// is never coming back.
+func (sr *SignerRemote) loop() {
+	failures := 0
+	for {
+		select {
+		case <-sr.quit:
+			return
+		default:
+		}
+
+		conn, err := sr.dialer.Dial()
+		if err != nil {
+			failures++
+			if failures >= defaultConnRetries {
+				sr.Logger.Error("SignerRemote: too many consecutive dial failures, giving up", "failures", failures, "err", err)
+				return
+			}
+			sr.Logger.Error("SignerRemote: failed to dial SocketPV, retrying", "err", err)
+			time.Sleep(defaultDialRetryWait)
+			continue
+		}
+		failures = 0
+
+		sr.Logger.Info("SignerRemote: connected to SocketPV")
+		if err := sr.serve(conn); err != nil {
+			sr.Logger.Error("SignerRemote: connection to SocketPV lost, reconnecting", "err", err)
+		}
+		conn.Close()
+	}
+}
+
+// serve handles requests on conn until it errors or the service is stopped.
+func (sr *SignerRemote) serve(conn net.Conn) error {
+	for {
+		select {
+		case <-sr.quit:
+			return nil
+		default:
+		}
+
+		conn.SetDeadline(time.Now().Add(defaultConnDeadline))
+
+		var req RemoteSignerMsg
+		if _, err := cdc.UnmarshalBinaryLengthPrefixedReader(conn, &req, 0); err != nil {
+			return fmt.Errorf("failed to read request: %v", err)
+		}
+
+		resp := sr.handle(req)
+
+		if _, err := cdc.MarshalBinaryLengthPrefixedWriter(conn, resp); err != nil {
+			return fmt.Errorf("failed to write response: %v", err)
+		}
+	}
+}
+
+// handle dispatches a single request to privVal and builds the matching reply.
+func (sr *SignerRemote) handle(req RemoteSignerMsg) RemoteSignerMsg {
+	switch r := req.(type) {
+	case *PubKeyRequest:
+		return &PubKeyResponse{PubKey: sr.privVal.GetPubKey()}
+
+	case *SignVoteRequest:
+		reply, err := sr.privVal.SignVote(r.Vote)
+		if err != nil {
+			reply.Error = remoteSignerErr(err)
+		}
+		return &reply
+
+	case *SignProposalRequest:
+		reply, err := sr.privVal.SignProposal(r.ChainID, r.Proposal)
+		if err != nil {
+			reply.Error = remoteSignerErr(err)
+		}
+		return &reply
+
+	case *SignHeartbeatRequest:
+		reply, err := sr.privVal.SignHeartbeat(r.ChainID, r.Heartbeat)
+		if err != nil {
+			reply.Error = remoteSignerErr(err)
+		}
+		return &reply
+
+	case *types.PingRequest:
+		return &types.PingResponse{}
+
+	default:
+		return &types.RemoteSignerError{Description: fmt.Sprintf("unknown request type %T", req)}
+	}
+}
@@ -0,0 +1,48 @@
+package privval
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	// defaultConnDeadline bounds both a single request/response round-trip
+	// and how long SignerRemote will block waiting to read the next
+	// request on an idle connection. It must stay larger than
+	// defaultPingInterval: SocketPV's keepalive ping is itself a request
+	// read by that same idle wait, so if the deadline were shorter than
+	// the interval between pings, the connection would always time out
+	// and be torn down before a ping ever arrived to keep it alive.
+	defaultConnDeadline = 10 * time.Second
+	// defaultConnRetries bounds consecutive dial failures in
+	// SignerRemote's reconnect loop before it gives up and stops.
+	defaultConnRetries   = 50
+	defaultDialRetryWait = time.Second
+	defaultPingInterval  = 3 * time.Second
+)
+
+// SignerDialer establishes outbound connections to a SocketPV listener.
+// SignerRemote uses it to (re)connect, so that the remote signer is always
+// the side initiating the network connection -- the validator node never
+// has to know how to reach the signer.
+type SignerDialer interface {
+	Dial() (net.Conn, error)
+}
+
+// DialTCPFn dials addr over TCP.
+func DialTCPFn(addr string, timeout time.Duration) SignerDialer {
+	return dialerFunc(func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, timeout)
+	})
+}
+
+// DialUnixFn dials the unix socket at path.
+func DialUnixFn(path string, timeout time.Duration) SignerDialer {
+	return dialerFunc(func() (net.Conn, error) {
+		return net.DialTimeout("unix", path, timeout)
+	})
+}
+
+type dialerFunc func() (net.Conn, error)
+
+func (f dialerFunc) Dial() (net.Conn, error) { return f() }
@@ -0,0 +1,196 @@
+package privval
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	amino "github.com/tendermint/go-amino"
+
+	"github.com/tendermint/tendermint/crypto"
+	cryptoamino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+var cdc = amino.NewCodec()
+
+func init() {
+	cryptoamino.RegisterAmino(cdc)
+	RegisterRemoteSignerMsg(cdc)
+}
+
+// SocketPV implements types.PrivValidator by forwarding every request to a
+// remote signer (a SignerRemote) over a length-prefixed amino stream. It
+// listens for the signer to dial in -- the signer is the one holding key
+// material, so it is the one that should be free to live behind a NAT or
+// be restarted independently of the validator.
+//
+// A background ping loop keeps the connection's deadline moving and logs
+// an error when a ping fails. SocketPV accepts a single connection, in
+// OnStart; once that connection dies, every request fails and no new
+// connection is accepted.
+type SocketPV struct {
+	cmn.BaseService
+
+	listener net.Listener
+
+	mtx   sync.Mutex
+	conn  net.Conn
+	pingC chan struct{}
+}
+
+// NewSocketPV returns a SocketPV that accepts connections on listener.
+func NewSocketPV(logger log.Logger, listener net.Listener) *SocketPV {
+	sc := &SocketPV{
+		listener: listener,
+		pingC:    make(chan struct{}),
+	}
+	sc.BaseService = *cmn.NewBaseService(logger, "SocketPV", sc)
+	return sc
+}
+
+// OnStart implements cmn.Service. It accepts the first connection from the
+// remote signer and starts the background ping loop.
+func (sc *SocketPV) OnStart() error {
+	conn, err := sc.listener.Accept()
+	if err != nil {
+		return fmt.Errorf("SocketPV: failed to accept connection from remote signer: %v", err)
+	}
+	sc.mtx.Lock()
+	sc.conn = conn
+	sc.mtx.Unlock()
+
+	go sc.pingLoop()
+	return nil
+}
+
+// OnStop implements cmn.Service.
+func (sc *SocketPV) OnStop() {
+	close(sc.pingC)
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	if sc.conn != nil {
+		sc.conn.Close()
+	}
+	sc.listener.Close()
+}
+
+// GetAddress implements types.PrivValidator.
+func (sc *SocketPV) GetAddress() types.Address {
+	pubKey := sc.GetPubKey()
+	return pubKey.Address()
+}
+
+// GetPubKey implements types.PrivValidator.
+func (sc *SocketPV) GetPubKey() crypto.PubKey {
+	resp, err := sc.request(&PubKeyRequest{})
+	if err != nil {
+		panic(fmt.Sprintf("SocketPV: failed to get pubkey from remote signer: %v", err))
+	}
+	pkResp, ok := resp.(*PubKeyResponse)
+	if !ok {
+		panic(fmt.Sprintf("SocketPV: unexpected response type %T to PubKeyRequest", resp))
+	}
+	if pkResp.Error != nil {
+		panic(fmt.Sprintf("SocketPV: remote signer refused to provide a pubkey: %v", pkResp.Error))
+	}
+	return pkResp.PubKey
+}
+
+// SignVote implements types.PrivValidator.
+func (sc *SocketPV) SignVote(vote *types.Vote) (types.SignVoteReply, error) {
+	resp, err := sc.request(&SignVoteRequest{Vote: vote})
+	if err != nil {
+		return types.SignVoteReply{}, err
+	}
+	reply, ok := resp.(*types.SignVoteReply)
+	if !ok {
+		return types.SignVoteReply{}, fmt.Errorf("SocketPV: unexpected response type %T to SignVoteRequest", resp)
+	}
+	if reply.Error != nil {
+		return *reply, reply.Error
+	}
+	return *reply, nil
+}
+
+// SignProposal implements types.PrivValidator.
+func (sc *SocketPV) SignProposal(chainID string, proposal *types.Proposal) (types.SignProposalReply, error) {
+	resp, err := sc.request(&SignProposalRequest{ChainID: chainID, Proposal: proposal})
+	if err != nil {
+		return types.SignProposalReply{}, err
+	}
+	reply, ok := resp.(*types.SignProposalReply)
+	if !ok {
+		return types.SignProposalReply{}, fmt.Errorf("SocketPV: unexpected response type %T to SignProposalRequest", resp)
+	}
+	if reply.Error != nil {
+		return *reply, reply.Error
+	}
+	return *reply, nil
+}
+
+// SignHeartbeat implements types.PrivValidator.
+func (sc *SocketPV) SignHeartbeat(chainID string, heartbeat *types.Heartbeat) (types.SignHeartbeatReply, error) {
+	resp, err := sc.request(&SignHeartbeatRequest{ChainID: chainID, Heartbeat: heartbeat})
+	if err != nil {
+		return types.SignHeartbeatReply{}, err
+	}
+	reply, ok := resp.(*types.SignHeartbeatReply)
+	if !ok {
+		return types.SignHeartbeatReply{}, fmt.Errorf("SocketPV: unexpected response type %T to SignHeartbeatRequest", resp)
+	}
+	if reply.Error != nil {
+		return *reply, reply.Error
+	}
+	return *reply, nil
+}
+
+// request writes req to the active connection and reads the matching
+// response, both under defaultConnDeadline. The whole round-trip is
+// serialized by sc.mtx: SignVote/SignProposal/SignHeartbeat and the
+// background pingLoop all share the one stream, and without the lock held
+// for the full write-then-read, two concurrent round-trips can interleave
+// frames and hand one caller another caller's response.
+func (sc *SocketPV) request(req RemoteSignerMsg) (RemoteSignerMsg, error) {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+
+	conn := sc.conn
+	if conn == nil {
+		return nil, fmt.Errorf("SocketPV: no connection to remote signer")
+	}
+
+	conn.SetDeadline(time.Now().Add(defaultConnDeadline))
+
+	if _, err := cdc.MarshalBinaryLengthPrefixedWriter(conn, req); err != nil {
+		return nil, fmt.Errorf("SocketPV: failed to write request: %v", err)
+	}
+
+	var resp RemoteSignerMsg
+	if _, err := cdc.UnmarshalBinaryLengthPrefixedReader(conn, &resp, 0); err != nil {
+		return nil, fmt.Errorf("SocketPV: failed to read response: %v", err)
+	}
+	return resp, nil
+}
+
+// pingLoop periodically pings the remote signer so that a dead connection
+// is noticed even while the validator is otherwise idle (e.g. between
+// blocks).
+func (sc *SocketPV) pingLoop() {
+	ticker := time.NewTicker(defaultPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sc.pingC:
+			return
+		case <-ticker.C:
+			if _, err := sc.request(&types.PingRequest{}); err != nil {
+				sc.Logger.Error("SocketPV: ping failed, remote signer may be down", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package privval
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSocketPVPubKey(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	mock := types.NewMockPV()
+	sc := NewSocketPV(log.TestingLogger(), listener)
+	sr := NewSignerRemote(log.TestingLogger(), mock, DialTCPFn(listener.Addr().String(), time.Second))
+
+	require.NoError(t, sr.Start())
+	defer sr.Stop()
+	require.NoError(t, sc.Start())
+	defer sc.Stop()
+
+	require.Equal(t, mock.GetPubKey(), sc.GetPubKey())
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/tendermint/go-amino"
@@ -11,10 +12,35 @@ import (
 )
 
 const (
-	// MaxEvidenceBytes is a maximum size of any evidence (including amino overhead).
-	MaxEvidenceBytes = 440
+	// MaxEvidenceBytesDuplicateVote is the maximum size of a
+	// DuplicateVoteEvidence (including amino overhead). Unlike
+	// ConflictingHeadersEvidence, its size does not depend on the
+	// validator set.
+	MaxEvidenceBytesDuplicateVote = 484
+
+	// MaxEvidenceBytesPerValidator is, per validator in the set, how many
+	// additional bytes a single Commit's precommit (address + signature +
+	// amino overhead) adds to its encoded size.
+	MaxEvidenceBytesPerValidator = 140
 )
 
+// MaxEvidenceBytes returns the maximum size, in bytes, that any single
+// piece of Evidence can encode to (including amino overhead) for a
+// validator set of numValidators. DuplicateVoteEvidence's size is
+// independent of the validator set, but ConflictingHeadersEvidence carries
+// two SignedHeaders -- two Headers plus two Commits, each with one
+// precommit per validator -- so a fixed constant can't bound both: it must
+// grow with numValidators.
+func MaxEvidenceBytes(numValidators int) int64 {
+	duplicateVote := int64(MaxEvidenceBytesDuplicateVote)
+	// two headers' worth of commits, each with numValidators precommits.
+	conflictingHeaders := duplicateVote + 2*int64(numValidators)*MaxEvidenceBytesPerValidator
+	if conflictingHeaders > duplicateVote {
+		return conflictingHeaders
+	}
+	return duplicateVote
+}
+
 // ErrEvidenceInvalid wraps a piece of evidence and the error denoting how or why it is invalid.
 type ErrEvidenceInvalid struct {
 	Evidence   Evidence
@@ -46,15 +72,26 @@ type Evidence interface {
 func RegisterEvidences(cdc *amino.Codec) {
 	cdc.RegisterInterface((*Evidence)(nil), nil)
 	cdc.RegisterConcrete(&DuplicateVoteEvidence{}, "tendermint/DuplicateVoteEvidence", nil)
+	cdc.RegisterConcrete(&ConflictingHeadersEvidence{}, "tendermint/ConflictingHeadersEvidence", nil)
+	cdc.RegisterConcrete(&LunaticValidatorEvidence{}, "tendermint/LunaticValidatorEvidence", nil)
 
 	// mocks
 	cdc.RegisterConcrete(MockGoodEvidence{}, "tendermint/MockGoodEvidence", nil)
 	cdc.RegisterConcrete(MockBadEvidence{}, "tendermint/MockBadEvidence", nil)
 }
 
-// MaxEvidenceBytesPerBlock returns the maximum evidence size per block.
-func MaxEvidenceBytesPerBlock(blockMaxBytes int) int {
-	return blockMaxBytes / 10
+// MaxEvidenceBytesPerBlock returns the maximum evidence size per block for a
+// validator set of numValidators. It is the usual 1/10th of the block's max
+// size, but never less than room for a single maximally-sized piece of
+// evidence -- otherwise a valid ConflictingHeadersEvidence for a large
+// validator set could never fit in any block and would be stuck pending
+// forever.
+func MaxEvidenceBytesPerBlock(blockMaxBytes int, numValidators int) int {
+	maxBytes := blockMaxBytes / 10
+	if maxPiece := int(MaxEvidenceBytes(numValidators)); maxPiece > maxBytes {
+		return maxPiece
+	}
+	return maxBytes
 }
 
 //-------------------------------------------
@@ -143,6 +180,269 @@ func (dve *DuplicateVoteEvidence) Equal(ev Evidence) bool {
 	return bytes.Equal(dveHash, evHash)
 }
 
+//-------------------------------------------
+
+// ConflictingHeadersEvidence proves a light-client fork attack: two
+// SignedHeaders at the same height, individually well-formed and each
+// committed to by a quorum of the same validator set, but committing to
+// different BlockIDs. Unlike DuplicateVoteEvidence, no single validator
+// needs to have signed two conflicting votes for the same H/R/S -- the
+// validator set as a whole has equivocated by signing two headers, which
+// is only observable by a light client that has fetched both.
+type ConflictingHeadersEvidence struct {
+	H1 *SignedHeader
+	H2 *SignedHeader
+}
+
+// ValidateBasic rejects a structurally malformed ConflictingHeadersEvidence
+// -- e.g. a nil H1/H2, or a SignedHeader missing its Header or Commit.
+// Height, String and VerifyWithValSet call it before they dereference
+// those fields; Hash does not dereference them. The evidence pool's
+// AddEvidence does not call it.
+func (ev *ConflictingHeadersEvidence) ValidateBasic() error {
+	if ev.H1 == nil || ev.H1.Header == nil || ev.H1.Commit == nil {
+		return errors.New("ConflictingHeadersEvidence: H1 is missing a header or commit")
+	}
+	if ev.H2 == nil || ev.H2.Header == nil || ev.H2.Commit == nil {
+		return errors.New("ConflictingHeadersEvidence: H2 is missing a header or commit")
+	}
+	return nil
+}
+
+// String returns a string representation of the evidence.
+func (ev *ConflictingHeadersEvidence) String() string {
+	if err := ev.ValidateBasic(); err != nil {
+		return fmt.Sprintf("ConflictingHeadersEvidence{invalid: %v}", err)
+	}
+	return fmt.Sprintf("ConflictingHeadersEvidence{H1: %v, H2: %v}", ev.H1.Header, ev.H2.Header)
+}
+
+// Height returns the height the conflicting headers were both committed
+// at, or 0 if the evidence is malformed.
+func (ev *ConflictingHeadersEvidence) Height() int64 {
+	if err := ev.ValidateBasic(); err != nil {
+		return 0
+	}
+	return ev.H1.Header.Height
+}
+
+// Address is not well-defined for ConflictingHeadersEvidence: it implicates
+// every validator that signed both commits, not a single validator, so it
+// returns nil. VerifyWithValSet checks their combined voting power but does
+// not return their addresses.
+func (ev *ConflictingHeadersEvidence) Address() []byte {
+	return nil
+}
+
+// Hash returns the hash of the evidence.
+func (ev *ConflictingHeadersEvidence) Hash() []byte {
+	return aminoHasher(ev).Hash()
+}
+
+// Verify always fails: a single pubkey is not enough context to verify
+// ConflictingHeadersEvidence, which must be checked against the full
+// validator set both headers claim to be committed by. Use VerifyWithValSet.
+func (ev *ConflictingHeadersEvidence) Verify(chainID string, pubKey crypto.PubKey) error {
+	return errors.New("ConflictingHeadersEvidence: Verify is a stub, use VerifyWithValSet instead")
+}
+
+// VerifyWithValSet checks that both headers are at the same height, that
+// their commits are each structurally valid and signed by vs, that they
+// commit to different BlockIDs, and that the validators who signed both
+// commits together hold more than 1/3 of vs's total voting power -- proof
+// that the validator set has collectively signed two conflicting headers.
+func (ev *ConflictingHeadersEvidence) VerifyWithValSet(chainID string, vs *ValidatorSet) error {
+	if err := ev.ValidateBasic(); err != nil {
+		return fmt.Errorf("ConflictingHeadersEvidence: %v", err)
+	}
+
+	if ev.H1.Header.Height != ev.H2.Header.Height {
+		return fmt.Errorf("ConflictingHeadersEvidence: headers are not at the same height: %d vs %d",
+			ev.H1.Header.Height, ev.H2.Header.Height)
+	}
+
+	if err := vs.VerifyCommit(chainID, ev.H1.Commit.BlockID, ev.H1.Header.Height, ev.H1.Commit); err != nil {
+		return fmt.Errorf("ConflictingHeadersEvidence: invalid commit for H1: %v", err)
+	}
+	if err := vs.VerifyCommit(chainID, ev.H2.Commit.BlockID, ev.H2.Header.Height, ev.H2.Commit); err != nil {
+		return fmt.Errorf("ConflictingHeadersEvidence: invalid commit for H2: %v", err)
+	}
+
+	if ev.H1.Commit.BlockID.Equals(ev.H2.Commit.BlockID) {
+		return errors.New("ConflictingHeadersEvidence: H1 and H2 commit to the same block, not a fork")
+	}
+
+	var sharedPower int64
+	for _, precommit := range ev.H1.Commit.Precommits {
+		if precommit == nil {
+			continue
+		}
+		_, val := vs.GetByAddress(precommit.ValidatorAddress)
+		if val == nil {
+			continue
+		}
+		if commitHasSignerAddress(ev.H2.Commit, precommit.ValidatorAddress) {
+			sharedPower += val.VotingPower
+		}
+	}
+
+	if sharedPower*3 <= vs.TotalVotingPower() {
+		return fmt.Errorf("ConflictingHeadersEvidence: signers common to both headers hold %d/%d of the voting power, need >1/3",
+			sharedPower, vs.TotalVotingPower())
+	}
+
+	return nil
+}
+
+// commitHasSignerAddress returns true if commit contains a precommit signed
+// by the validator at addr.
+func commitHasSignerAddress(commit *Commit, addr []byte) bool {
+	for _, precommit := range commit.Precommits {
+		if precommit != nil && bytes.Equal(precommit.ValidatorAddress, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal checks if two pieces of evidence are equal.
+func (ev *ConflictingHeadersEvidence) Equal(other Evidence) bool {
+	if _, ok := other.(*ConflictingHeadersEvidence); !ok {
+		return false
+	}
+	return bytes.Equal(aminoHasher(ev).Hash(), aminoHasher(other).Hash())
+}
+
+//-------------------------------------------
+
+// lunaticInvalidFields lists the Header fields LunaticValidatorEvidence is
+// allowed to accuse, i.e. the state-machine-derived hashes a light client
+// cannot recompute on its own and must instead cross-check against what was
+// actually committed.
+var lunaticInvalidFields = map[string]bool{
+	"AppHash":         true,
+	"ValidatorsHash":  true,
+	"ConsensusHash":   true,
+	"LastResultsHash": true,
+}
+
+// LunaticValidatorEvidence proves that a validator signed a vote for a
+// Header that is otherwise well-formed, but whose named InvalidHeaderField
+// carries a state-machine-derived value that diverges from what the rest of
+// the network actually committed to at that height -- the "lunatic
+// validator" attack: a validator that signs off on an application state no
+// one else agrees with, detectable by a light client bisecting
+// non-adjacent headers.
+type LunaticValidatorEvidence struct {
+	Header             *Header
+	Vote               *Vote
+	InvalidHeaderField string
+}
+
+// String returns a string representation of the evidence.
+func (ev *LunaticValidatorEvidence) String() string {
+	return fmt.Sprintf("LunaticValidatorEvidence{%s of %v, voted for by %X}",
+		ev.InvalidHeaderField, ev.Header, ev.Vote.ValidatorAddress)
+}
+
+// Height returns the height this evidence refers to.
+func (ev *LunaticValidatorEvidence) Height() int64 {
+	return ev.Header.Height
+}
+
+// Address returns the address of the validator that signed the lunatic header.
+func (ev *LunaticValidatorEvidence) Address() []byte {
+	return ev.Vote.ValidatorAddress
+}
+
+// Hash returns the hash of the evidence.
+func (ev *LunaticValidatorEvidence) Hash() []byte {
+	return aminoHasher(ev).Hash()
+}
+
+// Verify checks that the vote is signed by pubKey, that it votes for
+// ev.Header, and that InvalidHeaderField names a field this evidence type
+// knows how to accuse. It cannot, on its own, confirm the field is actually
+// wrong -- that requires a reference header from the evidence pool, so
+// callers should follow up with LunaticValidatorEvidenceVerifier.
+func (ev *LunaticValidatorEvidence) Verify(chainID string, pubKey crypto.PubKey) error {
+	if !lunaticInvalidFields[ev.InvalidHeaderField] {
+		return fmt.Errorf("LunaticValidatorEvidence: unknown invalid field %q", ev.InvalidHeaderField)
+	}
+
+	if !bytes.Equal(ev.Vote.ValidatorAddress, pubKey.Address()) {
+		return fmt.Errorf("LunaticValidatorEvidence: address (%X) doesn't match pubkey (%v - %X)",
+			ev.Vote.ValidatorAddress, pubKey, pubKey.Address())
+	}
+
+	if !pubKey.VerifyBytes(ev.Vote.SignBytes(), ev.Vote.Signature) {
+		return fmt.Errorf("LunaticValidatorEvidence Error verifying vote: %v", ErrVoteInvalidSignature)
+	}
+
+	if !bytes.Equal(ev.Vote.BlockID.Hash, ev.Header.Hash()) {
+		return errors.New("LunaticValidatorEvidence: vote does not match header")
+	}
+
+	return nil
+}
+
+// Equal checks if two pieces of evidence are equal.
+func (ev *LunaticValidatorEvidence) Equal(other Evidence) bool {
+	if _, ok := other.(*LunaticValidatorEvidence); !ok {
+		return false
+	}
+	return bytes.Equal(aminoHasher(ev).Hash(), aminoHasher(other).Hash())
+}
+
+// lunaticHeaderField extracts the named state-machine-derived field from h.
+func lunaticHeaderField(h *Header, field string) ([]byte, error) {
+	switch field {
+	case "AppHash":
+		return h.AppHash, nil
+	case "ValidatorsHash":
+		return h.ValidatorsHash, nil
+	case "ConsensusHash":
+		return h.ConsensusHash, nil
+	case "LastResultsHash":
+		return h.LastResultsHash, nil
+	default:
+		return nil, fmt.Errorf("LunaticValidatorEvidence: unknown invalid field %q", field)
+	}
+}
+
+// LunaticValidatorEvidenceVerifier completes the checks Verify cannot do
+// alone: it confirms ev.Vote/ev.Header are internally consistent and then
+// compares ev.InvalidHeaderField against the same field on committed, the
+// header the evidence pool knows was actually committed at that height. If
+// the two values match, ev.Header was not actually lunatic and an error is
+// returned.
+func LunaticValidatorEvidenceVerifier(ev *LunaticValidatorEvidence, chainID string, pubKey crypto.PubKey, committed *Header) error {
+	if err := ev.Verify(chainID, pubKey); err != nil {
+		return err
+	}
+
+	if ev.Header.Height != committed.Height {
+		return fmt.Errorf("LunaticValidatorEvidence: reference header is for height %d, evidence is for height %d",
+			committed.Height, ev.Header.Height)
+	}
+
+	got, err := lunaticHeaderField(ev.Header, ev.InvalidHeaderField)
+	if err != nil {
+		return err
+	}
+	want, err := lunaticHeaderField(committed, ev.InvalidHeaderField)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(got, want) {
+		return fmt.Errorf("LunaticValidatorEvidence: %s matches the header actually committed at height %d, not a provable fork",
+			ev.InvalidHeaderField, committed.Height)
+	}
+
+	return nil
+}
+
 //-----------------------------------------------------------------
 
 // UNSTABLE
@@ -10,15 +10,63 @@ import (
 
 // PrivValidator defines the functionality of a local Tendermint validator
 // that signs votes, proposals, and heartbeats, and never double signs.
+//
+// SignProposal and SignHeartbeat return their *Reply types, mirroring
+// SignVote, so that implementations backed by a remote signer (see the
+// privval package) have a wire-friendly way to report a signing failure --
+// e.g. a double-sign refusal from an HSM -- without resorting to the Go
+// error value, which cannot cross a socket.
 type PrivValidator interface {
 	GetAddress() Address // redundant since .PubKey().Address()
 	GetPubKey() crypto.PubKey
 
 	SignVote(vote *Vote) (SignVoteReply, error)
-	SignProposal(chainID string, proposal *Proposal) error
-	SignHeartbeat(chainID string, heartbeat *Heartbeat) error
+	SignProposal(chainID string, proposal *Proposal) (SignProposalReply, error)
+	SignHeartbeat(chainID string, heartbeat *Heartbeat) (SignHeartbeatReply, error)
 }
 
+//----------------------------------------
+// Remote signer wire replies.
+//
+// Each reply carries either the signed message or a RemoteSignerError, so
+// that a remote signer (see the privval package) can report *why* it
+// refused to sign -- e.g. "double sign prevented" vs. "I/O error" -- over
+// the wire instead of only as an in-process Go error.
+
+// RemoteSignerError is returned by a remote signer in place of a
+// signature when it refuses or fails to sign a request.
+type RemoteSignerError struct {
+	// Code is an implementation-defined error code; this package defines
+	// no specific values. It exists so a remote signer can distinguish
+	// error classes (e.g. double-sign prevention vs. connectivity) without
+	// parsing Description.
+	Code        int
+	Description string
+}
+
+func (e *RemoteSignerError) Error() string {
+	return fmt.Sprintf("remote signer returned error #%d: %s", e.Code, e.Description)
+}
+
+// SignProposalReply carries either a signed Proposal or a RemoteSignerError.
+type SignProposalReply struct {
+	Proposal *Proposal
+	Error    *RemoteSignerError
+}
+
+// SignHeartbeatReply carries either a signed Heartbeat or a RemoteSignerError.
+type SignHeartbeatReply struct {
+	Heartbeat *Heartbeat
+	Error     *RemoteSignerError
+}
+
+// PingRequest is sent periodically by a SocketPV client to detect a dead
+// remote signer connection.
+type PingRequest struct{}
+
+// PingResponse acknowledges a PingRequest.
+type PingResponse struct{}
+
 //----------------------------------------
 // Misc.
 
@@ -66,8 +114,7 @@ func (pv *MockPV) SignVote(vote *Vote) (SignVoteReply, error) {
 	signBytes := vote.SignBytes()
 	sig, err := pv.privKey.Sign(signBytes)
 	if err != nil {
-		// TODO(ismail): encapsulate error into reply!
-		return SignVoteReply{}, err
+		return SignVoteReply{Error: &RemoteSignerError{Description: err.Error()}}, err
 	}
 
 	return SignVoteReply{
@@ -77,24 +124,24 @@ func (pv *MockPV) SignVote(vote *Vote) (SignVoteReply, error) {
 }
 
 // Implements PrivValidator.
-func (pv *MockPV) SignProposal(chainID string, proposal *Proposal) error {
+func (pv *MockPV) SignProposal(chainID string, proposal *Proposal) (SignProposalReply, error) {
 	signBytes := proposal.SignBytes(chainID)
 	sig, err := pv.privKey.Sign(signBytes)
 	if err != nil {
-		return err
+		return SignProposalReply{Error: &RemoteSignerError{Description: err.Error()}}, err
 	}
 	proposal.Signature = sig
-	return nil
+	return SignProposalReply{Proposal: proposal}, nil
 }
 
 // signHeartbeat signs the heartbeat without any checking.
-func (pv *MockPV) SignHeartbeat(chainID string, heartbeat *Heartbeat) error {
+func (pv *MockPV) SignHeartbeat(chainID string, heartbeat *Heartbeat) (SignHeartbeatReply, error) {
 	sig, err := pv.privKey.Sign(heartbeat.SignBytes(chainID))
 	if err != nil {
-		return err
+		return SignHeartbeatReply{Error: &RemoteSignerError{Description: err.Error()}}, err
 	}
 	heartbeat.Signature = sig
-	return nil
+	return SignHeartbeatReply{Heartbeat: heartbeat}, nil
 }
 
 // String returns a string representation of the MockPV.